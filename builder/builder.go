@@ -0,0 +1,93 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package builder holds the mix-building logic used by the mixer
+// command-line tool. Of it, only adding RPMs to local repos (AddRPMList,
+// which drives hardlink and createrepo_c) is implemented; reading
+// builder.conf, writing a default config and initializing a mix all return
+// a "not implemented" error.
+package builder
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Version is the mixer-tools release version, reported by `mixer --version`.
+const Version = "devel"
+
+// UseNewSwupdServer selects the in-progress Go reimplementation of
+// swupd-server where it's available, instead of shelling out to the C tool.
+var UseNewSwupdServer bool
+
+// Repo describes one named local yum repository that RPMs can be added to.
+type Repo struct {
+	// Dir is the repo's directory, relative to RPMdir unless absolute.
+	Dir string
+}
+
+// Builder holds the configuration and state needed to build a mix.
+type Builder struct {
+	RPMdir      string
+	UpstreamURL string
+
+	// Repos holds the named local repos, keyed by name. repoDir registers a
+	// repo here the first time it is used. The main repo rooted at RPMdir
+	// itself (repo name "") is never stored here.
+	Repos map[string]Repo
+}
+
+// New returns a Builder with no configuration loaded yet.
+func New() *Builder {
+	return &Builder{
+		Repos: make(map[string]Repo),
+	}
+}
+
+// NewFromConfig returns a new Builder after running LoadBuilderConf on
+// configPath and then ReadBuilderConf. LoadBuilderConf is not implemented,
+// so NewFromConfig always returns its error.
+func NewFromConfig(configPath string) (*Builder, error) {
+	b := New()
+	if err := b.LoadBuilderConf(configPath); err != nil {
+		return nil, err
+	}
+	if err := b.ReadBuilderConf(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// CreateDefaultConfig is not implemented: it writes no builder.conf and
+// always returns a "not implemented" error.
+func (b *Builder) CreateDefaultConfig(localrpms bool) error {
+	return errors.New("not implemented")
+}
+
+// LoadBuilderConf is not implemented: it records no config file and always
+// returns a "not implemented" error.
+func (b *Builder) LoadBuilderConf(configPath string) error {
+	return errors.New("not implemented")
+}
+
+// ReadBuilderConf is not implemented: it parses no config into b and always
+// returns a "not implemented" error.
+func (b *Builder) ReadBuilderConf() error {
+	return errors.New("not implemented")
+}
+
+// InitMix is not implemented: it creates no mix workspace and always
+// returns a "not implemented" error.
+func (b *Builder) InitMix(clearVersion, mixVersion string, allBundles bool, upstreamURL string) error {
+	return errors.New("not implemented")
+}
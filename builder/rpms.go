@@ -0,0 +1,95 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// repoDir returns the directory of the named local repo, creating it (and
+// registering it in b.Repos) if this is the first time it's been used. The
+// empty name refers to the main repo rooted at RPMdir.
+func (b *Builder) repoDir(repo string) (string, error) {
+	if repo == "" {
+		return b.RPMdir, nil
+	}
+
+	if r, ok := b.Repos[repo]; ok {
+		dir := r.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(b.RPMdir, dir)
+		}
+		return dir, nil
+	}
+
+	dir := filepath.Join(b.RPMdir, repo)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrapf(err, "couldn't create repo directory %s", dir)
+	}
+	b.Repos[repo] = Repo{Dir: repo}
+	return dir, nil
+}
+
+// AddRPMList hardlinks rpms into the named local repo (the main RPMdir repo,
+// if repo is empty) and regenerates that repo's metadata with createrepo_c.
+func (b *Builder) AddRPMList(repo string, rpms []string) error {
+	dir, err := b.repoDir(repo)
+	if err != nil {
+		return err
+	}
+
+	for _, rpm := range rpms {
+		dst := filepath.Join(dir, filepath.Base(rpm))
+		if samePath(rpm, dst) {
+			continue
+		}
+		if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "couldn't remove stale %s", dst)
+		}
+		if err := os.Link(rpm, dst); err != nil {
+			return errors.Wrapf(err, "couldn't link %s into %s", rpm, dir)
+		}
+	}
+
+	// Collapse any duplicate files within the repo's directory (and its
+	// subdirectories) down to hardlinks to keep the mix workspace's disk
+	// usage down. Other repos' directories are not scanned.
+	hardlink := exec.Command("hardlink", dir)
+	if out, err := hardlink.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "hardlink on %s failed: %s", dir, out)
+	}
+
+	createrepo := exec.Command("createrepo_c", dir)
+	if out, err := createrepo.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "createrepo_c on %s failed: %s", dir, out)
+	}
+	return nil
+}
+
+func samePath(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
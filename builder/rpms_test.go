@@ -0,0 +1,122 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepoDirMainRepo(t *testing.T) {
+	b := New()
+	b.RPMdir = "/some/rpmdir"
+
+	dir, err := b.repoDir("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != b.RPMdir {
+		t.Errorf("repoDir(\"\") = %q, want %q", dir, b.RPMdir)
+	}
+	if len(b.Repos) != 0 {
+		t.Errorf("repoDir(\"\") registered a repo, want none: %v", b.Repos)
+	}
+}
+
+func TestRepoDirCreatesAndRegisters(t *testing.T) {
+	b := New()
+	b.RPMdir = t.TempDir()
+
+	want := filepath.Join(b.RPMdir, "extra")
+	dir, err := b.repoDir("extra")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != want {
+		t.Errorf("repoDir(%q) = %q, want %q", "extra", dir, want)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("repoDir(%q) didn't create %q", "extra", dir)
+	}
+	if got, ok := b.Repos["extra"]; !ok || got.Dir != "extra" {
+		t.Errorf("repoDir(%q) didn't register in Repos: %v", "extra", b.Repos)
+	}
+}
+
+func TestRepoDirReusesRegistered(t *testing.T) {
+	b := New()
+	b.RPMdir = t.TempDir()
+
+	first, err := b.repoDir("extra")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a repo registered with an absolute Dir elsewhere on disk.
+	abs := t.TempDir()
+	b.Repos["elsewhere"] = Repo{Dir: abs}
+	dir, err := b.repoDir("elsewhere")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != abs {
+		t.Errorf("repoDir(%q) = %q, want absolute %q", "elsewhere", dir, abs)
+	}
+
+	second, err := b.repoDir("extra")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second != first {
+		t.Errorf("repoDir(%q) returned %q on second call, want cached %q", "extra", second, first)
+	}
+	if len(b.Repos) != 2 {
+		t.Errorf("repoDir() re-registered an already-known repo: %v", b.Repos)
+	}
+}
+
+func TestSamePath(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	if err := ioutil.WriteFile(a, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b := filepath.Join(dir, "b")
+	if err := ioutil.WriteFile(b, []byte("y"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Link(a, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{"hardlinked files are the same path", a, link, true},
+		{"distinct files are not the same path", a, b, false},
+		{"a missing file is never the same path", a, filepath.Join(dir, "missing"), false},
+	}
+	for _, tt := range tests {
+		if got := samePath(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: samePath(%q, %q) = %v, want %v", tt.name, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// withCompletionUpstream points completeUpstreamLatest at a test server for
+// the duration of the test, restoring the real upstreamurl and HTTP client
+// settings afterwards.
+func withCompletionUpstream(t *testing.T, srv *httptest.Server, clientTimeout time.Duration) {
+	t.Helper()
+
+	origURL := initFlags.upstreamurl
+	origClient := completionHTTPClient
+	initFlags.upstreamurl = srv.URL
+	completionHTTPClient = &http.Client{Timeout: clientTimeout}
+
+	t.Cleanup(func() {
+		initFlags.upstreamurl = origURL
+		completionHTTPClient = origClient
+		srv.Close()
+	})
+}
+
+func TestCompleteUpstreamLatestHappyPath(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("30000"))
+	}))
+	withCompletionUpstream(t, srv, completionHTTPTimeout)
+
+	got, directive := completeUpstreamLatest(nil, nil, "")
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v, want ShellCompDirectiveNoFileComp", directive)
+	}
+	want := "30000\tlatest version published upstream"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("completeUpstreamLatest() = %v, want [%q]", got, want)
+	}
+}
+
+func TestCompleteUpstreamLatestNon200(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	withCompletionUpstream(t, srv, completionHTTPTimeout)
+
+	got, directive := completeUpstreamLatest(nil, nil, "")
+	if got != nil {
+		t.Errorf("completeUpstreamLatest() = %v, want nil", got)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v, want ShellCompDirectiveNoFileComp", directive)
+	}
+}
+
+func TestCompleteUpstreamLatestTimeout(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	withCompletionUpstream(t, srv, 10*time.Millisecond)
+	// Cleanups run last-in first-out, so this unblocks the handler before
+	// withCompletionUpstream's srv.Close, which waits for it to return.
+	t.Cleanup(func() { close(block) })
+
+	got, directive := completeUpstreamLatest(nil, nil, "")
+	if got != nil {
+		t.Errorf("completeUpstreamLatest() = %v, want nil", got)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v, want ShellCompDirectiveNoFileComp", directive)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configKeyFlags maps each configKeys entry to every pflag.Flag bound to it
+// (more than one command can expose the same setting, e.g. --rpmdir on both
+// init and add-rpms), so resolveKey can check whether the user actually
+// passed one of them (Flag.Changed) rather than relying on viper.IsSet,
+// which is also true for a flag's unchanged default.
+var configKeyFlags = map[string][]*pflag.Flag{}
+
+// registerConfigFlag records that f (on whichever command defined it) backs
+// the named config key, for both override application (applyOverride) and
+// the "mixer config" precedence report (resolveKey).
+func registerConfigFlag(key string, f *pflag.Flag) {
+	configKeyFlags[key] = append(configKeyFlags[key], f)
+}
+
+// legacyINIAliases maps the field names used in the legacy, INI-style
+// builder.conf (as they land under Viper's "builder" section once
+// lowercased) to the flat key names the rest of the cmd package reads via
+// resolveKey/applyOverride. Viper doesn't flatten a decoded INI section on
+// its own, and the legacy field names don't match the flat ones 1:1 (e.g.
+// UPSTREAM_URL vs. upstreamurl), so the mapping has to be explicit.
+var legacyINIAliases = map[string]string{
+	"rpmdir":       "rpmdir",
+	"upstream_url": "upstreamurl",
+	"clearver":     "clearver",
+	"mixver":       "mixver",
+	"local_rpms":   "localrpms",
+}
+
+// legacyConfigValues holds the flattened, aliased values read out of a
+// legacy builder.conf by flattenLegacyConfig. It's nil/empty unless the
+// loaded config file was INI.
+var legacyConfigValues = map[string]string{}
+
+// flattenLegacyConfig re-homes the values Viper decoded under the "builder"
+// INI section to the flat keys in configKeys, so resolveKey/applyOverride
+// can find them the same way they'd find a value from builder.yaml/.toml/
+// .json, which are written with those flat keys at the top level already.
+func flattenLegacyConfig() {
+	legacyConfigValues = make(map[string]string)
+	for legacyKey, flatKey := range legacyINIAliases {
+		if v, ok := viper.GetStringMap("builder")[legacyKey]; ok {
+			legacyConfigValues[flatKey] = fmt.Sprintf("%v", v)
+		}
+	}
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Print the effective mixer configuration",
+	Long: `Print the effective mixer configuration and, for each setting, whether
+builder.conf or a MIXER_* environment variable supplied it, or else the
+default of the flag that backs it. The settings' flags belong to other
+commands (init, add-rpms), not to "mixer config", so it never reports a
+flag as the source. This is meant to help debug CI environments where
+several of those can be in play at once.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if file := viper.ConfigFileUsed(); file != "" {
+			fmt.Printf("config file: %s\n", file)
+		} else {
+			fmt.Println("config file: (none found)")
+		}
+		for _, key := range configKeys {
+			value, source := resolveKey(key)
+			fmt.Printf("%-12s %-30v %s\n", key, value, source)
+		}
+	},
+}
+
+// resolveKey returns key's effective value and which tier supplied it, in
+// order of precedence: flag, MIXER_* environment variable, config file
+// (legacy builder.conf via legacyConfigValues, or a flat key in builder.
+// yaml/.toml/.json via Viper directly), or a bound flag's default.
+func resolveKey(key string) (value string, source string) {
+	for _, f := range configKeyFlags[key] {
+		if f.Changed {
+			return f.Value.String(), "flag"
+		}
+	}
+	if v, ok := os.LookupEnv("MIXER_" + strings.ToUpper(key)); ok {
+		return v, "env"
+	}
+	if v, ok := legacyConfigValues[key]; ok {
+		return v, "file"
+	}
+	if viper.ConfigFileUsed() != "" && viper.InConfig(key) {
+		return viper.GetString(key), "file"
+	}
+	if flags := configKeyFlags[key]; len(flags) > 0 {
+		return flags[0].Value.String(), "default"
+	}
+	return "", "default"
+}
+
+// applyOverride is resolveKey restricted to the tiers a command needs to
+// explicitly apply on top of its own flag parsing: env and file. A "flag"
+// source needs no action (cobra already wrote it into the bound variable),
+// and "default" means there's nothing to override.
+func applyOverride(key string) (string, bool) {
+	value, source := resolveKey(key)
+	if source == "env" || source == "file" {
+		return value, true
+	}
+	return "", false
+}
+
+// configSource reports where a config key's effective value came from; see
+// resolveKey.
+func configSource(key string) string {
+	_, source := resolveKey(key)
+	return source
+}
@@ -0,0 +1,46 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestConfigSource(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("upstream-url", "https://download.clearlinux.org", "")
+	registerConfigFlag("upstreamurl", flags.Lookup("upstream-url"))
+	defer delete(configKeyFlags, "upstreamurl")
+
+	if got := configSource("upstreamurl"); got != "default" {
+		t.Errorf("unchanged flag with no env/file: got %q, want %q", got, "default")
+	}
+
+	_ = os.Setenv("MIXER_UPSTREAMURL", "https://example.com")
+	defer func() { _ = os.Unsetenv("MIXER_UPSTREAMURL") }()
+	if got := configSource("upstreamurl"); got != "env" {
+		t.Errorf("env set, flag unchanged: got %q, want %q", got, "env")
+	}
+
+	if err := flags.Set("upstream-url", "https://flag.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if got := configSource("upstreamurl"); got != "flag" {
+		t.Errorf("flag explicitly set should win over env: got %q, want %q", got, "flag")
+	}
+}
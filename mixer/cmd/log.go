@@ -0,0 +1,117 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"runtime/pprof"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var log = logrus.New()
+
+var logFlags struct {
+	level  string
+	format string
+	file   string
+}
+
+// currentCmd is set by RootCmd's PersistentPreRunE so fail/failf can report
+// which command failed without threading a *cobra.Command through every
+// call site.
+var currentCmd *cobra.Command
+
+func init() {
+	RootCmd.PersistentFlags().StringVar(&logFlags.level, "log-level", "info", "Logging level: debug, info, warn, or error")
+	RootCmd.PersistentFlags().StringVar(&logFlags.format, "log-format", "text", "Logging output format: text or json")
+	RootCmd.PersistentFlags().StringVar(&logFlags.file, "log-file", "", "Additionally write logs to this file")
+
+	cobra.OnInitialize(setupLogging)
+}
+
+// setupLogging applies --log-level/--log-format/--log-file. It runs, via
+// cobra.OnInitialize, after flags are parsed but before any command's Run.
+func setupLogging() {
+	level, err := logrus.ParseLevel(logFlags.level)
+	if err != nil {
+		failf("invalid --log-level %q: %s", logFlags.level, err)
+	}
+	log.SetLevel(level)
+
+	switch logFlags.format {
+	case "text":
+		log.SetFormatter(&logrus.TextFormatter{})
+	case "json":
+		log.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		failf("invalid --log-format %q: must be text or json", logFlags.format)
+	}
+
+	out := io.Writer(os.Stderr)
+	if logFlags.file != "" {
+		f, err := os.OpenFile(logFlags.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			failf("couldn't open --log-file %s: %s", logFlags.file, err)
+		}
+		out = io.MultiWriter(out, f)
+	}
+	log.SetOutput(out)
+}
+
+// fail logs err as a structured error event - including the failing
+// command and its arguments, plus a stack trace at --log-level=debug - then
+// exits with status 1.
+//
+// A command's terminal failure reason must never be silenceable by
+// --log-level, so this writes the entry directly rather than through
+// log.Error, which logrus would drop if the configured level were above
+// Error (e.g. --log-level=panic).
+func fail(err error) {
+	if rootCmdFlags.cpuProfile != "" {
+		log.Debug("stopping CPU profile")
+		pprof.StopCPUProfile()
+	}
+
+	fields := logrus.Fields{}
+	if currentCmd != nil {
+		fields["command"] = currentCmd.CommandPath()
+		fields["args"] = currentCmd.Flags().Args()
+	}
+	if log.IsLevelEnabled(logrus.DebugLevel) {
+		fields["stack"] = string(debug.Stack())
+	}
+
+	entry := log.WithFields(fields)
+	entry.Message = err.Error()
+	entry.Level = logrus.ErrorLevel
+	entry.Time = time.Now()
+	if serialized, ferr := log.Formatter.Format(entry); ferr == nil {
+		_, _ = log.Out.Write(serialized)
+	} else {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+	}
+	os.Exit(1)
+}
+
+func failf(format string, a ...interface{}) {
+	fail(errors.Errorf(format, a...))
+}
@@ -0,0 +1,47 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestFailIgnoresLogLevel guards against fail()'s error message being
+// suppressed by a restrictive --log-level, by re-executing the test binary
+// as a subprocess (since fail calls os.Exit).
+func TestFailIgnoresLogLevel(t *testing.T) {
+	if os.Getenv("MIXER_TEST_FAIL_SUBPROCESS") == "1" {
+		log.SetLevel(logrus.PanicLevel)
+		log.SetOutput(os.Stdout)
+		fail(errors.New("boom"))
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestFailIgnoresLogLevel")
+	cmd.Env = append(os.Environ(), "MIXER_TEST_FAIL_SUBPROCESS=1")
+	out, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("expected exit status 1, got err=%v output=%s", err, out)
+	}
+	if !strings.Contains(string(out), "boom") {
+		t.Errorf("fail() output missing even at --log-level=panic: %q", out)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/clearlinux/mixer-tools/builder"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var manFlags struct {
+	dir string
+}
+
+var manCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate troff man pages for mixer and its subcommands",
+	Long: `Generate troff man pages for mixer and its subcommands, one file per
+command (e.g. mixer-init.1, mixer-add-rpms.1), suitable for packaging under
+/usr/share/man/man1.`,
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		header := &doc.GenManHeader{
+			Title:   "MIXER",
+			Section: "1",
+			Source:  "Mixer " + builder.Version,
+		}
+		if err := doc.GenManTree(RootCmd, header, manFlags.dir); err != nil {
+			fail(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(manCmd)
+
+	manCmd.Flags().StringVar(&manFlags.dir, "dir", ".", "Directory to write the generated man pages to")
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime/pprof"
 	"sort"
 	"strconv"
@@ -27,16 +28,75 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var config string
 
+// configKeys lists the settings that can be supplied via builder.conf (in any
+// of its supported formats), a flag, or a MIXER_* environment variable. It
+// also drives the "mixer config" subcommand's precedence report.
+var configKeys = []string{"rpmdir", "upstreamurl", "clearver", "mixver", "localrpms"}
+
+// configFileType maps a builder config file's extension to the Viper decoder
+// that can parse it. The legacy builder.conf is INI, not a format Viper's own
+// SupportedExts recognizes by its "conf" extension, so it needs to be named
+// explicitly rather than left to Viper's extension sniffing.
+func configFileType(path string) string {
+	switch ext := strings.TrimPrefix(filepath.Ext(path), "."); ext {
+	case "", "conf":
+		return "ini"
+	default:
+		return ext
+	}
+}
+
+// initViper wires up the precedence chain for the settings in configKeys:
+// flags win over MIXER_* environment variables, which win over whatever is
+// found in builder.conf/.yaml/.toml/.json.
+func initViper() {
+	viper.SetEnvPrefix("mixer")
+	viper.AutomaticEnv()
+
+	if config != "" {
+		loadConfigFile(config)
+		return
+	}
+
+	// No --config given: look for a builder.conf/.yaml/.toml/.json in the
+	// working directory, preferring the legacy builder.conf.
+	for _, name := range []string{"builder.conf", "builder.yaml", "builder.toml", "builder.json"} {
+		if _, err := os.Stat(name); err != nil {
+			continue
+		}
+		loadConfigFile(name)
+		return
+	}
+}
+
+// loadConfigFile reads path into Viper, additionally flattening its legacy
+// INI section into the flat keys the rest of the cmd package expects if
+// path is a builder.conf.
+func loadConfigFile(path string) {
+	viper.SetConfigFile(path)
+	fileType := configFileType(path)
+	viper.SetConfigType(fileType)
+	if err := viper.ReadInConfig(); err != nil {
+		failf("couldn't read config file %s: %s", path, err)
+	}
+	if fileType == "ini" {
+		flattenLegacyConfig()
+	}
+}
+
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:  "mixer",
 	Long: `Mixer is a tool used to compose OS update content and images.`,
 
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		currentCmd = cmd
+
 		if rootCmdFlags.cpuProfile != "" {
 			f, err := os.Create(rootCmdFlags.cpuProfile)
 			if err != nil {
@@ -46,6 +106,7 @@ var RootCmd = &cobra.Command{
 			if err != nil {
 				failf("couldn't start profiling: %s", err)
 			}
+			log.WithField("file", rootCmdFlags.cpuProfile).Debug("started CPU profile")
 		}
 		// Both --version and --check should work regardless of the regular
 		// check for external programs.
@@ -68,6 +129,7 @@ var RootCmd = &cobra.Command{
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
 		if rootCmdFlags.cpuProfile != "" {
 			pprof.StopCPUProfile()
+			log.Debug("stopped CPU profile")
 		}
 	},
 
@@ -89,6 +151,7 @@ type initCmdFlags struct {
 	clearver    int
 	mixver      int
 	upstreamurl string
+	rpmdir      string
 }
 
 var initFlags initCmdFlags
@@ -113,6 +176,36 @@ var initCmd = &cobra.Command{
 		if err := b.ReadBuilderConf(); err != nil {
 			fail(err)
 		}
+		if v, ok := applyOverride("clearver"); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				failf("invalid clearver %q: %s", v, err)
+			}
+			initFlags.clearver = n
+		}
+		if v, ok := applyOverride("mixver"); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				failf("invalid mixver %q: %s", v, err)
+			}
+			initFlags.mixver = n
+		}
+		if v, ok := applyOverride("upstreamurl"); ok {
+			initFlags.upstreamurl = v
+		}
+		if v, ok := applyOverride("localrpms"); ok {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				failf("invalid localrpms %q: %s", v, err)
+			}
+			localrpms = parsed
+		}
+		if v, ok := applyOverride("rpmdir"); ok {
+			initFlags.rpmdir = v
+		}
+		if initFlags.rpmdir != "" {
+			b.RPMdir = initFlags.rpmdir
+		}
 		err := b.InitMix(strconv.Itoa(initFlags.clearver), strconv.Itoa(initFlags.mixver), initFlags.all, initFlags.upstreamurl)
 		if err != nil {
 			fail(err)
@@ -129,6 +222,8 @@ func Execute() {
 }
 
 func init() {
+	cobra.OnInitialize(initViper)
+
 	RootCmd.PersistentFlags().StringVar(&rootCmdFlags.cpuProfile, "cpu-profile", "", "write CPU profile to a file")
 	_ = RootCmd.PersistentFlags().MarkHidden("cpu-profile")
 
@@ -145,6 +240,15 @@ func init() {
 	initCmd.Flags().IntVar(&initFlags.mixver, "mix-version", 0, "Supply the Mix version to build")
 	initCmd.Flags().StringVar(&config, "config", "", "Supply a specific builder.conf to use for mixing")
 	initCmd.Flags().StringVar(&initFlags.upstreamurl, "upstream-url", "https://download.clearlinux.org", "Supply an upstream URL to use for mixing")
+	initCmd.Flags().StringVar(&initFlags.rpmdir, "rpmdir", "", "Override RPMDIR from the configuration")
+
+	registerConfigFlag("clearver", initCmd.Flags().Lookup("clear-version"))
+	registerConfigFlag("mixver", initCmd.Flags().Lookup("mix-version"))
+	registerConfigFlag("upstreamurl", initCmd.Flags().Lookup("upstream-url"))
+	registerConfigFlag("localrpms", initCmd.Flags().Lookup("local-rpms"))
+	registerConfigFlag("rpmdir", initCmd.Flags().Lookup("rpmdir"))
+
+	RootCmd.AddCommand(configCmd)
 
 	// mark required flags
 	_ = cobra.MarkFlagRequired(initCmd.Flags(), "clear-version")
@@ -190,14 +294,7 @@ func checkAllDeps() bool {
 	}
 	sort.Strings(allDeps)
 
-	var max int
-	for _, dep := range allDeps {
-		if len(dep) > max {
-			max = len(dep)
-		}
-	}
-
-	fmt.Println("Programs used by Mixer commands:")
+	log.Info("checking programs used by Mixer commands")
 	ok := true
 	for i, dep := range allDeps {
 		if i > 0 && allDeps[i] == allDeps[i-1] {
@@ -206,24 +303,11 @@ func checkAllDeps() bool {
 		}
 		_, err := exec.LookPath(dep)
 		if err != nil {
-			fmt.Printf("  %-*s not found\n", max, dep)
+			log.WithField("program", dep).Warn("not found")
 			ok = false
 		} else {
-			fmt.Printf("  %-*s ok\n", max, dep)
+			log.WithField("program", dep).Info("ok")
 		}
 	}
 	return ok
 }
-
-func fail(err error) {
-	if rootCmdFlags.cpuProfile != "" {
-		pprof.StopCPUProfile()
-	}
-	fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
-	os.Exit(1)
-}
-
-func failf(format string, a ...interface{}) {
-	fmt.Fprintf(os.Stderr, fmt.Sprintf("ERROR: %s\n", format), a...)
-	os.Exit(1)
-}
@@ -0,0 +1,105 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestConfigFileType(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"builder.conf", "ini"},
+		{"/etc/mixer/builder.conf", "ini"},
+		{"builder.yaml", "yaml"},
+		{"builder.toml", "toml"},
+		{"builder.json", "json"},
+		{"builder", "ini"},
+	}
+	for _, tt := range tests {
+		if got := configFileType(tt.path); got != tt.want {
+			t.Errorf("configFileType(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestInitViperLoadsLegacyBuilderConf exercises the actual consumption path
+// (resolveKey/applyOverride on the flat key names) rather than just Viper's
+// raw, section-namespaced decode of the INI file.
+func TestInitViperLoadsLegacyBuilderConf(t *testing.T) {
+	dir := t.TempDir()
+	confPath := dir + "/builder.conf"
+	const contents = `[Builder]
+RPMDIR = /local/rpms
+UPSTREAM_URL = https://example.com
+CLEARVER = 30000
+MIXVER = 10
+LOCAL_RPMS = true
+`
+	if err := ioutil.WriteFile(confPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config = confPath
+	defer func() { config = "" }()
+
+	initViper()
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"rpmdir", "/local/rpms"},
+		{"upstreamurl", "https://example.com"},
+		{"clearver", "30000"},
+		{"mixver", "10"},
+		{"localrpms", "true"},
+	}
+	for _, tt := range tests {
+		value, ok := applyOverride(tt.key)
+		if !ok {
+			t.Errorf("applyOverride(%q): no value found from %s", tt.key, confPath)
+			continue
+		}
+		if value != tt.want {
+			t.Errorf("applyOverride(%q) = %q, want %q", tt.key, value, tt.want)
+		}
+		if source := configSource(tt.key); source != "file" {
+			t.Errorf("configSource(%q) = %q, want %q", tt.key, source, "file")
+		}
+	}
+}
+
+// TestInitRPMDirFlagRegistered guards against `mixer init` losing its
+// MIXER_RPMDIR/builder.conf RPMDIR override: init must register its own
+// --rpmdir flag with the shared "rpmdir" config key, the same as add-rpms.
+func TestInitRPMDirFlagRegistered(t *testing.T) {
+	if initCmd.Flags().Lookup("rpmdir") == nil {
+		t.Fatal("init has no --rpmdir flag")
+	}
+
+	var found bool
+	for _, f := range configKeyFlags["rpmdir"] {
+		if f == initCmd.Flags().Lookup("rpmdir") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error(`init's --rpmdir flag is not registered under configKeyFlags["rpmdir"]`)
+	}
+}
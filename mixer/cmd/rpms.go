@@ -15,7 +15,7 @@
 package cmd
 
 import (
-	"io/ioutil"
+	"path/filepath"
 
 	"github.com/clearlinux/mixer-tools/builder"
 
@@ -23,22 +23,43 @@ import (
 )
 
 var addRPMCmd = &cobra.Command{
-	Use:   "add-rpms",
+	Use:   "add-rpms [rpm-glob ...]",
 	Short: "Add RPMs to local yum repository",
-	Long:  `Add RPMS from the configured RPMDIR to local yum repository`,
-	Run:   runAddRPM,
+	Long: `Add RPMs from the configured RPMDIR to a local yum repository.
+
+With --all, every RPM under RPMDIR is added, as before. Otherwise, pass one
+or more globs (resolved relative to RPMDIR, e.g. "kernel-*.rpm" or
+"userspace/*.rpm") to add just those RPMs. Use --repo to target a named
+local repo other than the default one, and --include/--exclude to further
+filter the resulting set by filename glob.`,
+	Run: runAddRPM,
 }
 
 var rpmCmds = []*cobra.Command{
 	addRPMCmd,
 }
 
+var addRPMFlags struct {
+	rpmdir  string
+	all     bool
+	repo    string
+	include []string
+	exclude []string
+}
+
 func init() {
 	for _, cmd := range rpmCmds {
 		RootCmd.AddCommand(cmd)
 		cmd.Flags().StringVarP(&config, "config", "c", "", "Builder config to use")
 	}
 
+	addRPMCmd.Flags().StringVar(&addRPMFlags.rpmdir, "rpmdir", "", "Override RPMDIR from the configuration")
+	addRPMCmd.Flags().BoolVar(&addRPMFlags.all, "all", false, "Add every RPM under RPMDIR")
+	addRPMCmd.Flags().StringVar(&addRPMFlags.repo, "repo", "", "Local repo to add the RPMs to (defaults to the main RPMDIR repo)")
+	addRPMCmd.Flags().StringSliceVar(&addRPMFlags.include, "include", nil, "Only add RPMs whose filename matches one of these globs")
+	addRPMCmd.Flags().StringSliceVar(&addRPMFlags.exclude, "exclude", nil, "Skip RPMs whose filename matches one of these globs")
+	registerConfigFlag("rpmdir", addRPMCmd.Flags().Lookup("rpmdir"))
+
 	externalDeps[addRPMCmd] = []string{
 		"createrepo_c",
 		"hardlink",
@@ -46,19 +67,87 @@ func init() {
 }
 
 func runAddRPM(cmd *cobra.Command, args []string) {
+	if !addRPMFlags.all && len(args) == 0 {
+		failf("specify one or more RPM globs to add, or --all to add every RPM under RPMDIR")
+	}
+
 	b, err := builder.NewFromConfig(config)
 	if err != nil {
 		fail(err)
 	}
+	if dir, ok := applyOverride("rpmdir"); ok {
+		b.RPMdir = dir
+	}
 	if b.RPMdir == "" {
 		failf("RPMDIR not set in configuration")
 	}
-	rpms, err := ioutil.ReadDir(b.RPMdir)
+
+	patterns := args
+	if addRPMFlags.all {
+		patterns = []string{"*.rpm"}
+	}
+
+	var rpms []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(b.RPMdir, pattern))
+		if err != nil {
+			failf("bad RPM glob %q: %s", pattern, err)
+		}
+		rpms = append(rpms, matches...)
+	}
+	rpms, err = filterRPMs(rpms, addRPMFlags.include, addRPMFlags.exclude)
 	if err != nil {
-		failf("cannot read RPMDIR: %s", err)
+		fail(err)
 	}
-	err = b.AddRPMList(rpms)
+	if len(rpms) == 0 {
+		failf("no RPMs under %s matched", b.RPMdir)
+	}
+
+	err = b.AddRPMList(addRPMFlags.repo, rpms)
 	if err != nil {
 		fail(err)
 	}
 }
+
+// filterRPMs keeps only the paths whose filename matches one of includes (if
+// any are given), and drops any that match one of excludes.
+func filterRPMs(paths []string, includes []string, excludes []string) ([]string, error) {
+	var filtered []string
+	for _, path := range paths {
+		name := filepath.Base(path)
+
+		if len(includes) > 0 {
+			matched, err := matchesAny(name, includes)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		excluded, err := matchesAny(name, excludes)
+		if err != nil {
+			return nil, err
+		}
+		if excluded {
+			continue
+		}
+
+		filtered = append(filtered, path)
+	}
+	return filtered, nil
+}
+
+func matchesAny(name string, globs []string) (bool, error) {
+	for _, glob := range globs {
+		matched, err := filepath.Match(glob, name)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,95 @@
+// Copyright © 2017 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		name  string
+		globs []string
+		want  bool
+	}{
+		{"kernel-4.19.rpm", []string{"kernel-*.rpm"}, true},
+		{"kernel-4.19.rpm", []string{"userspace-*.rpm"}, false},
+		{"kernel-4.19.rpm", nil, false},
+		{"kernel-4.19.rpm", []string{"userspace-*.rpm", "kernel-*.rpm"}, true},
+	}
+	for _, tt := range tests {
+		got, err := matchesAny(tt.name, tt.globs)
+		if err != nil {
+			t.Fatalf("matchesAny(%q, %v): %s", tt.name, tt.globs, err)
+		}
+		if got != tt.want {
+			t.Errorf("matchesAny(%q, %v) = %v, want %v", tt.name, tt.globs, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesAnyBadGlob(t *testing.T) {
+	if _, err := matchesAny("a.rpm", []string{"["}); err == nil {
+		t.Error("expected an error for a malformed glob, got nil")
+	}
+}
+
+func TestFilterRPMs(t *testing.T) {
+	paths := []string{
+		"/rpms/kernel-4.19.rpm",
+		"/rpms/kernel-4.19-dev.rpm",
+		"/rpms/userspace-1.0.rpm",
+	}
+
+	tests := []struct {
+		name     string
+		includes []string
+		excludes []string
+		want     []string
+	}{
+		{
+			name: "no filters keeps everything",
+			want: paths,
+		},
+		{
+			name:     "include restricts to matches",
+			includes: []string{"kernel-*.rpm"},
+			want:     []string{"/rpms/kernel-4.19.rpm", "/rpms/kernel-4.19-dev.rpm"},
+		},
+		{
+			name:     "exclude drops matches",
+			excludes: []string{"*-dev.rpm"},
+			want:     []string{"/rpms/kernel-4.19.rpm", "/rpms/userspace-1.0.rpm"},
+		},
+		{
+			name:     "exclude wins over include",
+			includes: []string{"kernel-*.rpm"},
+			excludes: []string{"*-dev.rpm"},
+			want:     []string{"/rpms/kernel-4.19.rpm"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterRPMs(paths, tt.includes, tt.excludes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterRPMs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}